@@ -0,0 +1,96 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package immuadmin
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/spf13/cobra"
+)
+
+func (cl *commandline) dump(cmd *cobra.Command) {
+	registerHomedirFlag(cmd)
+
+	ccmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Dump the database to stdout or to the file specified with --output",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			startTx, err := cmd.Flags().GetUint64("start-tx")
+			if err != nil {
+				return err
+			}
+
+			compression, err := cmd.Flags().GetString("compression")
+			if err != nil {
+				return err
+			}
+
+			kind, err := compressionFromFlag(compression)
+			if err != nil {
+				return err
+			}
+
+			outputPath, err := cmd.Flags().GetString("output")
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if outputPath != "" {
+				f, err := os.Create(outputPath)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				out = f
+			}
+
+			progress := func(tx uint64) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "\rdumped tx %d", tx)
+			}
+
+			err = cl.immuClient.Dump(cmd.Context(), &schema.DumpRequest{StartTx: startTx, Compression: kind}, out, progress)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.ErrOrStderr(), "\ndump complete")
+			return nil
+		},
+	}
+
+	ccmd.Flags().Uint64("start-tx", 0, "resume a previous dump starting at this tx (0 dumps from the beginning)")
+	ccmd.Flags().String("compression", "gzip", "wire compression for dumped chunks: none, gzip or zstd")
+	ccmd.Flags().String("output", "", "file to write the dump to (defaults to stdout)")
+
+	cmd.AddCommand(ccmd)
+}
+
+func compressionFromFlag(name string) (schema.Compression, error) {
+	switch name {
+	case "none":
+		return schema.Compression_NONE, nil
+	case "gzip":
+		return schema.Compression_GZIP, nil
+	case "zstd":
+		return schema.Compression_ZSTD, nil
+	default:
+		return schema.Compression_NONE, fmt.Errorf("unknown compression %q, expected none, gzip or zstd", name)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package immuadmin
+
+import (
+	"github.com/codenotary/immudb/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// plaintextCredentialsFlag opts out of the keyring-backed credentials store,
+// keeping the old plaintext-file behaviour. It exists for CI and headless
+// setups where there's neither a reachable OS keyring nor a TTY to prompt
+// for the fallback passphrase.
+const plaintextCredentialsFlag = "plaintext-credentials"
+
+// registerHomedirFlag adds --plaintext-credentials to cmd as a persistent
+// flag, so it's visible on cmd and every subcommand registered under it.
+// It's a no-op if the flag is already registered, since cmd is the shared
+// root command and every top-level command registration (dump, restore, ...)
+// calls this on its way in.
+func registerHomedirFlag(cmd *cobra.Command) {
+	if cmd.PersistentFlags().Lookup(plaintextCredentialsFlag) != nil {
+		return
+	}
+
+	cmd.PersistentFlags().Bool(plaintextCredentialsFlag, false, "use a plaintext credentials file instead of the OS keyring")
+}
+
+// homedirServiceFor picks the HomedirService backend a commandline should
+// use: SecureHomedirService by default, or the plaintext one when
+// --plaintext-credentials is set. login_test.go drives both backends through
+// this function rather than constructing them directly, so the flag's
+// behaviour - not just each backend in isolation - is what's under test.
+func homedirServiceFor(plaintextCredentials bool, passphraseReader client.PassphraseReader) client.HomedirService {
+	if plaintextCredentials {
+		return client.NewHomedirService()
+	}
+
+	return client.NewSecureHomedirService(passphraseReader)
+}
+
+// homedirServiceFromFlags reads --plaintext-credentials off cmd and resolves
+// it to the HomedirService backend homedirServiceFor would build for that
+// value, so a command that has already called registerHomedirFlag can go
+// straight from its parsed flags to the backend a commandline should use.
+func homedirServiceFromFlags(cmd *cobra.Command, passphraseReader client.PassphraseReader) client.HomedirService {
+	plaintextCredentials, _ := cmd.Flags().GetBool(plaintextCredentialsFlag)
+	return homedirServiceFor(plaintextCredentials, passphraseReader)
+}
@@ -19,6 +19,7 @@ package immuadmin
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/codenotary/immudb/pkg/auth"
 	"io/ioutil"
@@ -100,58 +101,105 @@ func (c scIClientInnerMock) Login(ctx context.Context, user []byte, pass []byte)
 }
 
 func TestCommandLine_LoginLogout(t *testing.T) {
+	// hds is exercised through the HomedirService interface only, so the
+	// login/logout flow must behave the same whether credentials land in
+	// a plaintext file or go through the keyring-backed implementation.
+	backends := map[string]client.HomedirService{
+		"plaintext": homedirServiceFor(true, &pwrMock{}),
+		"secure":    homedirServiceFor(false, &pwrMock{}),
+	}
+
+	for name, hds := range backends {
+		t.Run(name, func(t *testing.T) {
+			options := server.Options{}.WithAuth(true).WithInMemoryStore(true)
+			bs := servertest.NewBufconnServer(options)
+			bs.Start()
+
+			cmd := cobra.Command{}
+			dialOptions := []grpc.DialOption{
+				grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure(),
+			}
+			cliopt := Options()
+			cliopt.DialOptions = &dialOptions
+			cmdl := commandline{
+				options:        cliopt,
+				immuClient:     &scIClientInnerMock{cliopt, *new(client.ImmuClient)},
+				passwordReader: &pwrMock{},
+				context:        context.Background(),
+				hds:            hds,
+			}
+			cmdl.login(&cmd)
+
+			b := bytes.NewBufferString("")
+			cmd.SetOut(b)
+			cmd.SetArgs([]string{"login", "immudb"})
+			cmd.Execute()
+			out, err := ioutil.ReadAll(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.Contains(t, string(out), "logged in")
+
+			cmdlo := commandline{
+				options:        cliopt,
+				immuClient:     &scIClientMock{*new(client.ImmuClient)},
+				passwordReader: &pwrMock{},
+				context:        context.Background(),
+				hds:            hds,
+			}
+			b1 := bytes.NewBufferString("")
+			logoutcmd := cobra.Command{}
+			logoutcmd.SetOut(b1)
+			logoutcmd.SetArgs([]string{"logout"})
+
+			cmdlo.logout(&logoutcmd)
+
+			logoutcmd.Execute()
+			out1, err1 := ioutil.ReadAll(b1)
+			if err1 != nil {
+				t.Fatal(err1)
+			}
+			assert.Contains(t, string(out1), "logged out")
+		})
+	}
+}
+
+func TestCommandLine_CheckLoggedIn(t *testing.T) {
 	options := server.Options{}.WithAuth(true).WithInMemoryStore(true)
 	bs := servertest.NewBufconnServer(options)
 	bs.Start()
 
 	cmd := cobra.Command{}
+	cl := new(commandline)
+	cl.context = context.Background()
+	cl.passwordReader = &pwrMock{}
 	dialOptions := []grpc.DialOption{
 		grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure(),
 	}
-	cliopt := Options()
-	cliopt.DialOptions = &dialOptions
-	cmdl := commandline{
-		options:        cliopt,
-		immuClient:     &scIClientInnerMock{cliopt, *new(client.ImmuClient)},
-		passwordReader: &pwrMock{},
-		context:        context.Background(),
-		hds:            client.NewHomedirService(),
-	}
-	cmdl.login(&cmd)
 
-	b := bytes.NewBufferString("")
-	cmd.SetOut(b)
 	cmd.SetArgs([]string{"login", "immudb"})
 	cmd.Execute()
-	out, err := ioutil.ReadAll(b)
-	if err != nil {
-		t.Fatal(err)
-	}
-	assert.Contains(t, string(out), "logged in")
-
-	cmdlo := commandline{
-		options:        cliopt,
-		immuClient:     &scIClientMock{*new(client.ImmuClient)},
-		passwordReader: &pwrMock{},
-		context:        context.Background(),
-		hds:            client.NewHomedirService(),
-	}
-	b1 := bytes.NewBufferString("")
-	logoutcmd := cobra.Command{}
-	logoutcmd.SetOut(b1)
-	logoutcmd.SetArgs([]string{"logout"})
 
-	cmdlo.logout(&logoutcmd)
+	cl.options = Options()
+	cl.options.DialOptions = &dialOptions
+	cl.login(&cmd)
 
-	logoutcmd.Execute()
-	out1, err1 := ioutil.ReadAll(b1)
-	if err1 != nil {
-		t.Fatal(err1)
+	cmd1 := cobra.Command{}
+	cl1 := new(commandline)
+	cl1.context = context.Background()
+	cl1.passwordReader = &pwrMock{}
+	cl1.hds = &homedirServiceMock{}
+	dialOptions1 := []grpc.DialOption{
+		grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure(),
 	}
-	assert.Contains(t, string(out1), "logged out")
+
+	cl1.options = Options()
+	cl1.options.DialOptions = &dialOptions1
+	err := cl1.checkLoggedIn(&cmd1, nil)
+	assert.Nil(t, err)
 }
 
-func TestCommandLine_CheckLoggedIn(t *testing.T) {
+func TestCommandLine_CheckLoggedIn_SecureHomedir(t *testing.T) {
 	options := server.Options{}.WithAuth(true).WithInMemoryStore(true)
 	bs := servertest.NewBufconnServer(options)
 	bs.Start()
@@ -160,13 +208,11 @@ func TestCommandLine_CheckLoggedIn(t *testing.T) {
 	cl := new(commandline)
 	cl.context = context.Background()
 	cl.passwordReader = &pwrMock{}
+	cl.hds = homedirServiceFor(false, &pwrMock{})
 	dialOptions := []grpc.DialOption{
 		grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure(),
 	}
 
-	cmd.SetArgs([]string{"login", "immudb"})
-	cmd.Execute()
-
 	cl.options = Options()
 	cl.options.DialOptions = &dialOptions
 	cl.login(&cmd)
@@ -175,7 +221,7 @@ func TestCommandLine_CheckLoggedIn(t *testing.T) {
 	cl1 := new(commandline)
 	cl1.context = context.Background()
 	cl1.passwordReader = &pwrMock{}
-	cl1.hds = &homedirServiceMock{}
+	cl1.hds = cl.hds
 	dialOptions1 := []grpc.DialOption{
 		grpc.WithContextDialer(bs.Dialer), grpc.WithInsecure(),
 	}
@@ -186,6 +232,25 @@ func TestCommandLine_CheckLoggedIn(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+// TestHomedirServiceFromFlags_DefaultsToSecure checks that
+// homedirServiceFromFlags resolves to SecureHomedirService when
+// --plaintext-credentials is left unset, and to the plaintext backend once
+// it's set on the command line, with the flag registered the way dump and
+// restore register it.
+func TestHomedirServiceFromFlags_DefaultsToSecure(t *testing.T) {
+	cmd := &cobra.Command{}
+	registerHomedirFlag(cmd)
+
+	hds := homedirServiceFromFlags(cmd, &pwrMock{})
+	assert.Contains(t, fmt.Sprintf("%T", hds), "secure")
+
+	err := cmd.ParseFlags([]string{"--" + plaintextCredentialsFlag})
+	assert.NoError(t, err)
+
+	hds = homedirServiceFromFlags(cmd, &pwrMock{})
+	assert.Equal(t, client.NewHomedirService(), hds)
+}
+
 type homedirServiceMock struct {
 	client.HomedirService
 }
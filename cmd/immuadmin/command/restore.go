@@ -0,0 +1,55 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package immuadmin
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func (cl *commandline) restore(cmd *cobra.Command) {
+	registerHomedirFlag(cmd)
+
+	ccmd := &cobra.Command{
+		Use:   "restore <dump-file>",
+		Short: "Restore a database previously produced by dump into a new database",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			in, err := os.Open(args[0])
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+
+			progress := func(tx uint64) {
+				fmt.Fprintf(cmd.ErrOrStderr(), "\nrestored tx %d", tx)
+			}
+
+			resp, err := cl.immuClient.Restore(cmd.Context(), in, progress)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "\nrestore complete, last tx %d\n", resp.Tx)
+			return nil
+		},
+	}
+
+	cmd.AddCommand(ccmd)
+}
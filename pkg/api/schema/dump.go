@@ -0,0 +1,160 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schema
+
+import (
+	"github.com/golang/protobuf/proto"
+	"google.golang.org/grpc"
+)
+
+// Compression negotiates how a DumpChunk's Data is encoded on the wire. See
+// schema.proto for the message/RPC definitions this file implements.
+type Compression int32
+
+const (
+	Compression_NONE Compression = 0
+	Compression_GZIP Compression = 1
+	Compression_ZSTD Compression = 2
+)
+
+var Compression_name = map[int32]string{
+	0: "NONE",
+	1: "GZIP",
+	2: "ZSTD",
+}
+
+var Compression_value = map[string]int32{
+	"NONE": 0,
+	"GZIP": 1,
+	"ZSTD": 2,
+}
+
+func (x Compression) String() string {
+	return proto.EnumName(Compression_name, int32(x))
+}
+
+// DumpRequest parameterizes a Dump call.
+type DumpRequest struct {
+	StartTx              uint64 `protobuf:"varint,1,opt,name=startTx,proto3" json:"startTx,omitempty"`
+	Compression          Compression `protobuf:"varint,2,opt,name=compression,proto3,enum=schema.Compression" json:"compression,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized      []byte     `json:"-"`
+	XXX_sizecache         int32      `json:"-"`
+}
+
+func (m *DumpRequest) Reset()         { *m = DumpRequest{} }
+func (m *DumpRequest) String() string { return proto.CompactTextString(m) }
+func (*DumpRequest) ProtoMessage()    {}
+
+func (m *DumpRequest) GetStartTx() uint64 {
+	if m != nil {
+		return m.StartTx
+	}
+	return 0
+}
+
+func (m *DumpRequest) GetCompression() Compression {
+	if m != nil {
+		return m.Compression
+	}
+	return Compression_NONE
+}
+
+// DumpChunk carries one transaction's worth of compressed KVs plus its Alh.
+type DumpChunk struct {
+	Tx                   uint64 `protobuf:"varint,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	Compression          Compression `protobuf:"varint,2,opt,name=compression,proto3,enum=schema.Compression" json:"compression,omitempty"`
+	Data                 []byte `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	Alh                  []byte `protobuf:"bytes,4,opt,name=alh,proto3" json:"alh,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized      []byte     `json:"-"`
+	XXX_sizecache         int32      `json:"-"`
+}
+
+func (m *DumpChunk) Reset()         { *m = DumpChunk{} }
+func (m *DumpChunk) String() string { return proto.CompactTextString(m) }
+func (*DumpChunk) ProtoMessage()    {}
+
+func (m *DumpChunk) GetTx() uint64 {
+	if m != nil {
+		return m.Tx
+	}
+	return 0
+}
+
+func (m *DumpChunk) GetCompression() Compression {
+	if m != nil {
+		return m.Compression
+	}
+	return Compression_NONE
+}
+
+func (m *DumpChunk) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func (m *DumpChunk) GetAlh() []byte {
+	if m != nil {
+		return m.Alh
+	}
+	return nil
+}
+
+// RestoreResponse reports the last tx a Restore call committed.
+type RestoreResponse struct {
+	Tx                   uint64 `protobuf:"varint,1,opt,name=tx,proto3" json:"tx,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized      []byte  `json:"-"`
+	XXX_sizecache         int32   `json:"-"`
+}
+
+func (m *RestoreResponse) Reset()         { *m = RestoreResponse{} }
+func (m *RestoreResponse) String() string { return proto.CompactTextString(m) }
+func (*RestoreResponse) ProtoMessage()    {}
+
+func (m *RestoreResponse) GetTx() uint64 {
+	if m != nil {
+		return m.Tx
+	}
+	return 0
+}
+
+func init() {
+	proto.RegisterEnum("schema.Compression", Compression_name, Compression_value)
+	proto.RegisterType((*DumpRequest)(nil), "schema.DumpRequest")
+	proto.RegisterType((*DumpChunk)(nil), "schema.DumpChunk")
+	proto.RegisterType((*RestoreResponse)(nil), "schema.RestoreResponse")
+}
+
+// ImmuService_RestoreServer is the server-side stream handle for the
+// Restore RPC, mirroring the pre-existing ImmuService_DumpServer.
+type ImmuService_RestoreServer interface {
+	Recv() (*DumpChunk, error)
+	SendAndClose(*RestoreResponse) error
+	grpc.ServerStream
+}
+
+// ImmuService_RestoreClient is the client-side stream handle for the
+// Restore RPC.
+type ImmuService_RestoreClient interface {
+	Send(*DumpChunk) error
+	CloseAndRecv() (*RestoreResponse, error)
+	grpc.ClientStream
+}
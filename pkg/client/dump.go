@@ -0,0 +1,124 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/golang/protobuf/proto"
+)
+
+// Dump calls the server's Dump RPC with req and writes every DumpChunk it
+// receives to out, length-prefixed so Restore can read them back as
+// separate messages; progress, if non-nil, is called once per chunk after
+// it has been written, so a caller can report status or resume later from
+// chunk.Tx.
+func (c *immuClient) Dump(ctx context.Context, req *schema.DumpRequest, out io.Writer, progress func(tx uint64)) error {
+	stream, err := c.ServiceClient.Dump(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := writeDumpChunk(out, chunk); err != nil {
+			return err
+		}
+
+		if progress != nil {
+			progress(chunk.Tx)
+		}
+	}
+}
+
+// Restore reads the length-prefixed DumpChunks Dump wrote to in and streams
+// them to the server's Restore RPC, returning the response once the stream
+// is exhausted; progress, if non-nil, is called once per chunk sent.
+func (c *immuClient) Restore(ctx context.Context, in io.Reader, progress func(tx uint64)) (*schema.RestoreResponse, error) {
+	stream, err := c.ServiceClient.Restore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		chunk, err := readDumpChunk(in)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if err := stream.Send(chunk); err != nil {
+			return nil, err
+		}
+
+		if progress != nil {
+			progress(chunk.Tx)
+		}
+	}
+
+	return stream.CloseAndRecv()
+}
+
+// writeDumpChunk and readDumpChunk frame a DumpChunk with a 4-byte
+// big-endian length prefix, so a dump file written to a plain io.Writer can
+// be read back as a sequence of distinct messages by Restore.
+func writeDumpChunk(out io.Writer, chunk *schema.DumpChunk) error {
+	payload, err := proto.Marshal(chunk)
+	if err != nil {
+		return err
+	}
+
+	var size [4]byte
+	binary.BigEndian.PutUint32(size[:], uint32(len(payload)))
+
+	if _, err := out.Write(size[:]); err != nil {
+		return err
+	}
+	_, err = out.Write(payload)
+	return err
+}
+
+func readDumpChunk(in io.Reader) (*schema.DumpChunk, error) {
+	var size [4]byte
+	if _, err := io.ReadFull(in, size[:]); err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, binary.BigEndian.Uint32(size[:]))
+	if _, err := io.ReadFull(in, payload); err != nil {
+		return nil, err
+	}
+
+	chunk := &schema.DumpChunk{}
+	if err := proto.Unmarshal(payload, chunk); err != nil {
+		return nil, err
+	}
+
+	return chunk, nil
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// HomedirService abstracts persistence of immuadmin's login state under the
+// user's home directory, so the backend can be swapped between a plaintext
+// file (the historical behaviour, still used in CI and headless setups) and
+// a more secure implementation such as SecureHomedirService.
+type HomedirService interface {
+	FileExistsInUserHomeDir(pathToFile string) (bool, error)
+	WriteFileToUserHomeDir(content []byte, pathToFile string) error
+	ReadFileFromUserHomeDir(pathToFile string) (string, error)
+	DeleteFileFromUserHomeDir(pathToFile string) error
+}
+
+// homedirService is the original, plaintext implementation of HomedirService.
+type homedirService struct{}
+
+// NewHomedirService returns the plaintext HomedirService implementation.
+func NewHomedirService() HomedirService {
+	return homedirService{}
+}
+
+func (h homedirService) FileExistsInUserHomeDir(pathToFile string) (bool, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return false, err
+	}
+
+	_, err = os.Stat(filepath.Join(homeDir, pathToFile))
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+
+	return false, err
+}
+
+func (h homedirService) WriteFileToUserHomeDir(content []byte, pathToFile string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(homeDir, pathToFile), content, 0600)
+}
+
+func (h homedirService) ReadFileFromUserHomeDir(pathToFile string) (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	content, err := ioutil.ReadFile(filepath.Join(homeDir, pathToFile))
+	if err != nil {
+		return "", err
+	}
+
+	return string(content), nil
+}
+
+func (h homedirService) DeleteFileFromUserHomeDir(pathToFile string) error {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(filepath.Join(homeDir, pathToFile))
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/zalando/go-keyring"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keyringService = "immuadmin"
+	scryptSaltSize  = 16
+	scryptKeyLen    = 32
+)
+
+// PassphraseReader supplies the passphrase used to derive the encryption key
+// for the filesystem fallback, when no OS keyring is reachable.
+type PassphraseReader interface {
+	Read(msg string) ([]byte, error)
+}
+
+// secureHomedirService stores the login token in the platform keyring
+// (Keychain on macOS, DPAPI on Windows, Secret Service/libsecret on Linux).
+// When no keyring is reachable - e.g. a headless Linux box without a Secret
+// Service daemon - it falls back to an AES-GCM-encrypted file under the home
+// directory, keyed with scrypt over a passphrase obtained from
+// passphraseReader.
+type secureHomedirService struct {
+	fallback         HomedirService
+	passphraseReader PassphraseReader
+}
+
+// NewSecureHomedirService returns the keyring-backed HomedirService. It is
+// the default returned by Options(); NewHomedirService (selected via
+// --plaintext-credentials) keeps the old plaintext behaviour for CI and
+// headless setups that can't prompt for a passphrase.
+func NewSecureHomedirService(passphraseReader PassphraseReader) HomedirService {
+	return &secureHomedirService{
+		fallback:         NewHomedirService(),
+		passphraseReader: passphraseReader,
+	}
+}
+
+func (h *secureHomedirService) FileExistsInUserHomeDir(pathToFile string) (bool, error) {
+	if _, err := keyring.Get(keyringService, pathToFile); err == nil {
+		return true, nil
+	}
+
+	return h.fallback.FileExistsInUserHomeDir(encryptedFileName(pathToFile))
+}
+
+func (h *secureHomedirService) WriteFileToUserHomeDir(content []byte, pathToFile string) error {
+	if err := keyring.Set(keyringService, pathToFile, string(content)); err == nil {
+		return nil
+	}
+
+	encrypted, err := h.encrypt(content)
+	if err != nil {
+		return err
+	}
+
+	return h.fallback.WriteFileToUserHomeDir(encrypted, encryptedFileName(pathToFile))
+}
+
+func (h *secureHomedirService) ReadFileFromUserHomeDir(pathToFile string) (string, error) {
+	if content, err := keyring.Get(keyringService, pathToFile); err == nil {
+		return content, nil
+	}
+
+	encrypted, err := h.fallback.ReadFileFromUserHomeDir(encryptedFileName(pathToFile))
+	if err != nil {
+		return "", err
+	}
+
+	decrypted, err := h.decrypt([]byte(encrypted))
+	if err != nil {
+		return "", err
+	}
+
+	return string(decrypted), nil
+}
+
+func (h *secureHomedirService) DeleteFileFromUserHomeDir(pathToFile string) error {
+	keyringErr := keyring.Delete(keyringService, pathToFile)
+
+	fallbackErr := h.fallback.DeleteFileFromUserHomeDir(encryptedFileName(pathToFile))
+	if fallbackErr != nil && !os.IsNotExist(fallbackErr) {
+		return fallbackErr
+	}
+
+	if keyringErr != nil && keyringErr != keyring.ErrNotFound {
+		return keyringErr
+	}
+
+	return nil
+}
+
+func (h *secureHomedirService) deriveKey(salt []byte) ([]byte, error) {
+	passphrase, err := h.passphraseReader.Read("Passphrase to protect local credentials")
+	if err != nil {
+		return nil, err
+	}
+
+	return scrypt.Key(passphrase, salt, 1<<15, 8, 1, scryptKeyLen)
+}
+
+func (h *secureHomedirService) encrypt(plaintext []byte) ([]byte, error) {
+	salt := make([]byte, scryptSaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	key, err := h.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+
+	ciphertext := gcm.Seal(nonce, nonce, plaintext, nil)
+
+	return append(salt, ciphertext...), nil
+}
+
+func (h *secureHomedirService) decrypt(data []byte) ([]byte, error) {
+	if len(data) < scryptSaltSize {
+		return nil, fmt.Errorf("corrupted credentials file")
+	}
+
+	salt, ciphertext := data[:scryptSaltSize], data[scryptSaltSize:]
+
+	key, err := h.deriveKey(salt)
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, fmt.Errorf("corrupted credentials file")
+	}
+
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func encryptedFileName(pathToFile string) string {
+	return pathToFile + ".enc"
+}
@@ -17,7 +17,11 @@ limitations under the License.
 package database
 
 import (
+	"bytes"
+	"compress/gzip"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"math"
 	"os"
 	"path/filepath"
@@ -27,7 +31,9 @@ import (
 
 	"github.com/codenotary/immudb/pkg/api/schema"
 	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/golang/protobuf/proto"
 	"github.com/golang/protobuf/ptypes/empty"
+	"github.com/klauspost/compress/zstd"
 )
 
 //Db database instance
@@ -174,10 +180,58 @@ func (d *Db) CurrentRoot() (*schema.Root, error) {
 	return &schema.Root{Payload: &schema.RootIndex{Index: id, Root: alh[:]}}, nil
 }
 
-//SafeSet ...
+//SafeSet commits kv and returns the same inclusion+dual proof shape as SafeGet,
+// so a client can verify the entry was actually committed against its last
+// known root (opts.RootIndex) without trusting the server.
 func (d *Db) SafeSet(opts *schema.SafeSetOptions) (*schema.Proof, error) {
-	//return d.Store.SafeSet(*opts)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "SafeSet")
+	if opts == nil || opts.Kv == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	id, _, alh, err := d.Store.Commit([]*store.KV{{Key: opts.Kv.Key, Value: opts.Kv.Value}})
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error %v during %s", err, "SafeSet")
+	}
+
+	err = d.Store.ReadTx(id, d.tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inclusionProof, err := d.tx.Proof(opts.Kv.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &schema.Proof{
+		InclusionProof: inclusionProofTo(inclusionProof),
+	}
+
+	rootTx := d.Store.NewTx()
+
+	err = d.Store.ReadTx(opts.RootIndex.Index, rootTx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceTx, targetTx *store.Tx
+
+	if opts.RootIndex.Index <= id {
+		sourceTx = rootTx
+		targetTx = d.tx
+	} else {
+		sourceTx = d.tx
+		targetTx = rootTx
+	}
+
+	dualProof, err := d.Store.DualProof(sourceTx, targetTx)
+	if err != nil {
+		return nil, err
+	}
+
+	proof.DualProof = dualProofTo(dualProof)
+
+	return proof, nil
 }
 
 //SafeGet ...
@@ -272,10 +326,32 @@ func (d *Db) GetBatch(kl *schema.KeyList) (*schema.ItemList, error) {
 	return list, nil
 }
 
-// ExecAllOps ...
+// ExecAllOps commits every KV in operations.Operations as a single atomic
+// store.Commit batch, so either all of them land in the same tx or none do.
 func (d *Db) ExecAllOps(operations *schema.Ops) (*schema.Root, error) {
-	//return d.Store.ExecAllOps(operations)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "ExecAllOps")
+	if operations == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	entries := make([]*store.KV, len(operations.Operations))
+
+	for i, op := range operations.Operations {
+		kv, ok := op.Operation.(*schema.Op_KVs)
+		if !ok {
+			return nil, fmt.Errorf("unsupported operation type %T in ExecAllOps", op.Operation)
+		}
+
+		entries[i] = &store.KV{Key: kv.KVs.Key, Value: kv.KVs.Value}
+	}
+
+	id, _, alh, err := d.Store.Commit(entries)
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error %v during %s", err, "ExecAllOps")
+	}
+
+	return &schema.Root{
+		Payload: &schema.RootIndex{Index: id, Root: alh[:]},
+	}, nil
 }
 
 //Size ...
@@ -283,10 +359,24 @@ func (d *Db) Size() (uint64, error) {
 	return d.Store.TxCount(), nil
 }
 
-//Count ...
+//Count returns the number of committed entries whose key starts with prefix.Prefix.
 func (d *Db) Count(prefix *schema.KeyPrefix) (*schema.ItemsCount, error) {
-	//return d.Store.Count(*prefix)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "Count")
+	if prefix == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	snapshot, err := d.Store.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Close()
+
+	count, err := snapshot.CountWithPrefix(prefix.Prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.ItemsCount{Count: count}, nil
 }
 
 // CountAll ...
@@ -295,21 +385,90 @@ func (d *Db) CountAll() *schema.ItemsCount {
 	return nil
 }
 
-// Consistency ...
+// Consistency builds a dual proof between two arbitrary tx indices, index.Index
+// (the source) and index.TargetIndex (the target), instead of always proving
+// index.Index against the current head. A zero TargetIndex proves
+// index.Index against the current head.
 func (d *Db) Consistency(index *schema.Index) (*schema.DualProof, error) {
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "Consistency")
+	if index == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	targetID := index.TargetIndex
+	if targetID == 0 {
+		targetID, _ = d.Store.Alh()
+	}
+
+	sourceTx := d.Store.NewTx()
+	if err := d.Store.ReadTx(index.Index, sourceTx); err != nil {
+		return nil, err
+	}
+
+	targetTx := d.Store.NewTx()
+	if err := d.Store.ReadTx(targetID, targetTx); err != nil {
+		return nil, err
+	}
+
+	dualProof, err := d.Store.DualProof(sourceTx, targetTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return dualProofTo(dualProof), nil
 }
 
-// ByIndex ...
+// ByIndex returns the tx committed at index.Index.
 func (d *Db) ByIndex(index *schema.Index) (*schema.Tx, error) {
-	//return d.Store.ByIndex(*index)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "ByIndex")
+	if index == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	err := d.Store.ReadTx(index.Index, d.tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return txTo(d.tx), nil
 }
 
-//BySafeIndex ...
+//BySafeIndex returns the tx at sio.Index along with a dual proof against
+// sio.RootIndex, the same shape SafeGet uses to prove a key-value entry.
 func (d *Db) BySafeIndex(sio *schema.SafeIndexOptions) (*schema.VerifiedTx, error) {
-	//return d.Store.BySafeIndex(*sio)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "BySafeIndex")
+	if sio == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	err := d.Store.ReadTx(sio.Index, d.tx)
+	if err != nil {
+		return nil, err
+	}
+
+	rootTx := d.Store.NewTx()
+
+	err = d.Store.ReadTx(sio.RootIndex.Index, rootTx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceTx, targetTx *store.Tx
+
+	if sio.RootIndex.Index <= sio.Index {
+		sourceTx = rootTx
+		targetTx = d.tx
+	} else {
+		sourceTx = d.tx
+		targetTx = rootTx
+	}
+
+	dualProof, err := d.Store.DualProof(sourceTx, targetTx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &schema.VerifiedTx{
+		Tx:        txTo(d.tx),
+		DualProof: dualProofTo(dualProof),
+	}, nil
 }
 
 //History ...
@@ -361,62 +520,461 @@ func (d *Db) Health(*empty.Empty) (*schema.HealthResponse, error) {
 	return &schema.HealthResponse{Status: true, Version: fmt.Sprintf("%d", store.Version)}, nil
 }
 
-//ZAdd ...
+//ZAdd adds key to opts.Set with the given score, by committing a regular
+// entry under a score-ordered secondary key (see zsetKey). The referenced
+// key itself is untouched, so a ZAdd never overwrites the plain value at key.
 func (d *Db) ZAdd(opts *schema.ZAddOptions) (*schema.Root, error) {
-	//return d.Store.ZAdd(*opts)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "ZAdd")
+	if opts == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	id, _, alh, err := d.Store.Commit([]*store.KV{
+		{Key: zsetKey(opts.Set, opts.Score, opts.Key), Value: opts.Key},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error %v during %s", err, "ZAdd")
+	}
+
+	return &schema.Root{
+		Payload: &schema.RootIndex{Index: id, Root: alh[:]},
+	}, nil
 }
 
-// ZScan ...
+// ZScan iterates the score-ordered secondary keys of opts.Set within
+// [opts.Min, opts.Max] (bounds inclusive unless opts.MinExclusive /
+// opts.MaxExclusive are set), honouring opts.Offset, opts.Limit and
+// opts.Desc, and resolves each match back to its referenced key's value.
 func (d *Db) ZScan(opts *schema.ZScanOptions) (*schema.ZItemList, error) {
-	//return d.Store.ZScan(*opts)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "ZScan")
+	if opts == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	snapshot, err := d.Store.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Close()
+
+	// SeekKey/EndKey must bound the *bucket* for Min/Max, not just reuse the
+	// bare score prefix for both: that prefix sorts below every real entry at
+	// that score (see zsetScoreLowerBound), so using it as EndKey always
+	// excludes the Max bucket and using it as SeekKey always includes the Min
+	// bucket, regardless of MinExclusive/MaxExclusive. Picking the lower or
+	// upper bound of each bucket based on the exclusive flags makes both
+	// ends honor inclusive and exclusive ranges correctly.
+	seekKey := zsetScoreLowerBound(opts.Set, opts.Min)
+	if opts.MinExclusive {
+		seekKey = zsetScoreUpperBound(opts.Set, opts.Min)
+	}
+
+	endKey := zsetScoreUpperBound(opts.Set, opts.Max)
+	if opts.MaxExclusive {
+		endKey = zsetScoreLowerBound(opts.Set, opts.Max)
+	}
+
+	// A descending walk starts at SeekKey and stops at EndKey, so it starts
+	// from the Max-side bound and stops at the Min-side one.
+	if opts.Desc {
+		seekKey, endKey = endKey, seekKey
+	}
+
+	reader, err := snapshot.NewKeyReader(&store.KeyReaderSpec{
+		Prefix:        zsetPrefix(opts.Set),
+		SeekKey:       seekKey,
+		EndKey:        endKey,
+		InclusiveSeek: true,
+		InclusiveEnd:  true,
+		DescOrder:     opts.Desc,
+	})
+	if err == store.ErrNoMoreEntries {
+		return &schema.ZItemList{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit == 0 {
+		limit = math.MaxUint64
+	}
+
+	list := &schema.ZItemList{}
+	skipped := uint64(0)
+
+	for uint64(len(list.Items)) < limit {
+		zkey, _, _, err := reader.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if skipped < opts.Offset {
+			skipped++
+			continue
+		}
+
+		key := zsetReferencedKey(opts.Set, zkey)
+
+		_, id, err := snapshot.Get(key)
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.Store.ReadTx(id, d.tx)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := d.Store.ReadValue(d.tx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Items = append(list.Items, &schema.ZItem{
+			Item:  &schema.Item{Key: key, Value: val, Index: id},
+			Score: decodeScore(zkey[len(zsetPrefix(opts.Set)) : len(zsetPrefix(opts.Set))+8]),
+			Set:   opts.Set,
+		})
+	}
+
+	return list, nil
 }
 
-//SafeZAdd ...
+//SafeZAdd commits the score entry the same way ZAdd does, then returns the
+// same inclusion+dual proof shape as SafeSet so a client can verify the
+// score entry (not just the referenced key) was actually committed.
 func (d *Db) SafeZAdd(opts *schema.SafeZAddOptions) (*schema.Proof, error) {
-	//return d.Store.SafeZAdd(*opts)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "SafeZAdd")
+	if opts == nil || opts.Zopts == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	zkey := zsetKey(opts.Zopts.Set, opts.Zopts.Score, opts.Zopts.Key)
+
+	id, _, alh, err := d.Store.Commit([]*store.KV{{Key: zkey, Value: opts.Zopts.Key}})
+	if err != nil {
+		return nil, fmt.Errorf("unexpected error %v during %s", err, "SafeZAdd")
+	}
+
+	err = d.Store.ReadTx(id, d.tx)
+	if err != nil {
+		return nil, err
+	}
+
+	inclusionProof, err := d.tx.Proof(zkey)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &schema.Proof{
+		InclusionProof: inclusionProofTo(inclusionProof),
+	}
+
+	rootTx := d.Store.NewTx()
+
+	err = d.Store.ReadTx(opts.RootIndex.Index, rootTx)
+	if err != nil {
+		return nil, err
+	}
+
+	var sourceTx, targetTx *store.Tx
+
+	if opts.RootIndex.Index <= id {
+		sourceTx = rootTx
+		targetTx = d.tx
+	} else {
+		sourceTx = d.tx
+		targetTx = rootTx
+	}
+
+	dualProof, err := d.Store.DualProof(sourceTx, targetTx)
+	if err != nil {
+		return nil, err
+	}
+
+	proof.DualProof = dualProofTo(dualProof)
+
+	return proof, nil
 }
 
-//Scan ...
+//Scan returns, in key order, every committed entry whose key starts with
+// opts.Prefix, resuming from opts.SeekKey when set.
 func (d *Db) Scan(opts *schema.ScanOptions) (*schema.ItemList, error) {
-	//return d.Store.Scan(*opts)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "Scan")
+	if opts == nil {
+		return nil, store.ErrIllegalArguments
+	}
+
+	snapshot, err := d.Store.Snapshot()
+	if err != nil {
+		return nil, err
+	}
+	defer snapshot.Close()
+
+	reader, err := snapshot.NewKeyReader(&store.KeyReaderSpec{
+		SeekKey:       opts.SeekKey,
+		Prefix:        opts.Prefix,
+		InclusiveSeek: !opts.NotInclusive,
+		DescOrder:     opts.Reverse,
+	})
+	if err == store.ErrNoMoreEntries {
+		return &schema.ItemList{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	limit := opts.Limit
+	if limit == 0 {
+		limit = math.MaxUint64
+	}
+
+	list := &schema.ItemList{}
+
+	for uint64(len(list.Items)) < limit {
+		key, _, id, err := reader.Read()
+		if err == store.ErrNoMoreEntries {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		err = d.Store.ReadTx(id, d.tx)
+		if err != nil {
+			return nil, err
+		}
+
+		val, err := d.Store.ReadValue(d.tx, key)
+		if err != nil {
+			return nil, err
+		}
+
+		list.Items = append(list.Items, &schema.Item{Key: key, Value: val, Index: id})
+	}
+
+	return list, nil
 }
 
-//IScan ...
+//IScan returns a page of entries ordered by insertion index, identified by
+// opts.PageNumber and sized by opts.PageSize. The insertion index counts
+// every KV of every tx, not one slot per tx, so a page boundary can fall
+// in the middle of a multi-KV tx (as committed by SetBatch or ExecAllOps)
+// without dropping any of its entries.
 func (d *Db) IScan(opts *schema.IScanOptions) (*schema.Page, error) {
-	//return d.Store.IScan(*opts)
-	return nil, fmt.Errorf("Functionality not yet supported: %s", "IScan")
+	if opts == nil || opts.PageSize == 0 {
+		return nil, store.ErrIllegalArguments
+	}
+
+	first := opts.PageNumber*opts.PageSize + 1
+	last := first + opts.PageSize - 1
+
+	txCount := d.Store.TxCount()
+
+	page := &schema.Page{PageNum: opts.PageNumber}
+
+	var seen uint64
+
+	for txID := uint64(1); txID <= txCount && seen < last; txID++ {
+		err := d.Store.ReadTx(txID, d.tx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, e := range d.tx.Entries() {
+			seen++
+
+			if seen < first {
+				continue
+			}
+			if seen > last {
+				break
+			}
+
+			val, err := d.Store.ReadValue(d.tx, e.Key())
+			if err != nil {
+				return nil, err
+			}
+
+			page.Items = append(page.Items, &schema.Item{Key: e.Key(), Value: val, Index: txID})
+		}
+	}
+
+	page.More = seen > last
+
+	return page, nil
 }
 
-//Dump ...
-func (d *Db) Dump(in *empty.Empty, stream schema.ImmuService_DumpServer) error {
-	/*
-		kvChan := make(chan *pb.KVList)
-		done := make(chan bool)
-
-		retrieveLists := func() {
-			for {
-				list, more := <-kvChan
-				if more {
-					stream.Send(list)
-				} else {
-					done <- true
-					return
-				}
+// Dump streams every committed transaction, from req.StartTx onwards, as a
+// sequence of compressed chunks. Each chunk carries a batch of KVs plus the
+// Alh of the tx they belong to, so Restore can rebuild the Merkle state and
+// verify it incrementally instead of trusting the stream blindly.
+func (d *Db) Dump(req *schema.DumpRequest, stream schema.ImmuService_DumpServer) error {
+	if req == nil {
+		return store.ErrIllegalArguments
+	}
+
+	startTx := req.StartTx
+	if startTx == 0 {
+		startTx = 1
+	}
+
+	txCount := d.Store.TxCount()
+	if startTx > txCount {
+		return nil
+	}
+
+	tx := d.Store.NewTx()
+
+	for txID := startTx; txID <= txCount; txID++ {
+		err := d.Store.ReadTx(txID, tx)
+		if err != nil {
+			return err
+		}
+
+		entries := tx.Entries()
+		kvs := make([]*schema.KeyValue, len(entries))
+
+		for i, e := range entries {
+			val, err := d.Store.ReadValue(tx, e.Key())
+			if err != nil {
+				return err
 			}
+
+			kvs[i] = &schema.KeyValue{Key: e.Key(), Value: val}
+		}
+
+		payload, err := proto.Marshal(&schema.KVList{KVs: kvs})
+		if err != nil {
+			return err
+		}
+
+		compressed, err := compressChunk(payload, req.Compression)
+		if err != nil {
+			return err
+		}
+
+		alh := tx.Alh()
+
+		err = stream.Send(&schema.DumpChunk{
+			Tx:          txID,
+			Compression: req.Compression,
+			Data:        compressed,
+			Alh:         alh[:],
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	d.Logger.Debugf("Dump stream complete, sent tx %d..%d", startTx, txCount)
+
+	return nil
+}
+
+// Restore consumes a stream produced by Dump into this (freshly-created) Db,
+// verifying every chunk's Alh against the one recomputed by the local commit
+// before advancing to the next one. It fails closed on the first mismatch;
+// the mismatching chunk has already been committed locally by then, so the
+// Db holds every verified tx plus that last, unverified one.
+func (d *Db) Restore(stream schema.ImmuService_RestoreServer) (*schema.RestoreResponse, error) {
+	var lastTx uint64
+
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		payload, err := decompressChunk(chunk.Data, chunk.Compression)
+		if err != nil {
+			return nil, err
+		}
+
+		kvl := &schema.KVList{}
+		if err := proto.Unmarshal(payload, kvl); err != nil {
+			return nil, err
+		}
+
+		entries := make([]*store.KV, len(kvl.KVs))
+		for i, kv := range kvl.KVs {
+			entries[i] = &store.KV{Key: kv.Key, Value: kv.Value}
+		}
+
+		id, _, alh, err := d.Store.Commit(entries)
+		if err != nil {
+			return nil, err
+		}
+
+		if id != chunk.Tx {
+			return nil, fmt.Errorf("restore: expected to commit tx %d, got %d", chunk.Tx, id)
+		}
+
+		if !bytes.Equal(alh[:], chunk.Alh) {
+			return nil, fmt.Errorf("restore: alh mismatch at tx %d, stream may be corrupted or tampered with", id)
 		}
 
-		go retrieveLists()
-		err := d.Store.Dump(kvChan)
-		<-done
+		lastTx = id
+	}
+
+	d.Logger.Debugf("Restore complete, last tx committed %d", lastTx)
+
+	return &schema.RestoreResponse{Tx: lastTx}, nil
+}
+
+// compressChunk compresses payload according to the negotiated compression
+// kind. schema.Compression_NONE is the default so older clients that don't
+// set the field keep getting uncompressed chunks.
+func compressChunk(payload []byte, kind schema.Compression) ([]byte, error) {
+	switch kind {
+	case schema.Compression_NONE:
+		return payload, nil
+	case schema.Compression_GZIP:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(payload); err != nil {
+			return nil, err
+		}
+		if err := w.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	case schema.Compression_ZSTD:
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer enc.Close()
+		return enc.EncodeAll(payload, nil), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression kind %v", kind)
+	}
+}
 
-		d.Logger.Debugf("Dump stream complete")
-		return err
-	*/
-	return fmt.Errorf("Functionality not yet supported: %s", "Dump")
+func decompressChunk(payload []byte, kind schema.Compression) ([]byte, error) {
+	switch kind {
+	case schema.Compression_NONE:
+		return payload, nil
+	case schema.Compression_GZIP:
+		r, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		return ioutil.ReadAll(r)
+	case schema.Compression_ZSTD:
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		return dec.DecodeAll(payload, nil)
+	default:
+		return nil, fmt.Errorf("unsupported compression kind %v", kind)
+	}
 }
 
 func logErr(log logger.Logger, formattedMessage string, err error) error {
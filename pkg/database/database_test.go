@@ -0,0 +1,151 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"os"
+	"testing"
+
+	"github.com/codenotary/immudb/embedded/store"
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/codenotary/immudb/pkg/logger"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makeTestDb(t *testing.T) *Db {
+	dbDir, err := os.MkdirTemp("", "immudb-database-test")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dbDir) })
+
+	op := DefaultOption().WithDbRootPath(dbDir).WithDbName("test")
+
+	db, err := NewDb(op, logger.NewSimpleLogger("test", os.Stdout))
+	require.NoError(t, err)
+
+	return db
+}
+
+// TestSafeSet_TamperedValueFailsVerification checks that flipping a byte of
+// the value returned alongside a SafeSet proof makes verification fail,
+// i.e. the proof is actually bound to the committed value and not just to
+// the key.
+func TestSafeSet_TamperedValueFailsVerification(t *testing.T) {
+	testCases := []struct {
+		name    string
+		tamper  func(value []byte) []byte
+		wantErr bool
+	}{
+		{
+			name:    "untampered value verifies",
+			tamper:  func(value []byte) []byte { return value },
+			wantErr: false,
+		},
+		{
+			name: "flipped first byte fails verification",
+			tamper: func(value []byte) []byte {
+				tampered := append([]byte{}, value...)
+				tampered[0] ^= 0xff
+				return tampered
+			},
+			wantErr: true,
+		},
+		{
+			name: "truncated value fails verification",
+			tamper: func(value []byte) []byte {
+				return value[:len(value)-1]
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := makeTestDb(t)
+
+			root, err := db.CurrentRoot()
+			require.NoError(t, err)
+
+			key := []byte("key")
+			value := []byte("value")
+
+			proof, err := db.SafeSet(&schema.SafeSetOptions{
+				Kv:        &schema.KeyValue{Key: key, Value: value},
+				RootIndex: root.Payload,
+			})
+			require.NoError(t, err)
+
+			tampered := tc.tamper(value)
+
+			err = store.VerifyInclusion(proof.InclusionProof, key, tampered)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+// TestConsistency_BetweenArbitraryIndices checks that Consistency proves
+// two arbitrary tx indices against each other, not just the latest one
+// against the current head.
+func TestConsistency_BetweenArbitraryIndices(t *testing.T) {
+	db := makeTestDb(t)
+
+	var firstID, secondID uint64
+
+	for i := 0; i < 3; i++ {
+		root, err := db.Set(&schema.KeyValue{Key: []byte("key"), Value: []byte("value")})
+		require.NoError(t, err)
+
+		if i == 0 {
+			firstID = root.Payload.Index
+		}
+		if i == 1 {
+			secondID = root.Payload.Index
+		}
+	}
+
+	dualProof, err := db.Consistency(&schema.Index{Index: firstID, TargetIndex: secondID})
+	require.NoError(t, err)
+	assert.NotNil(t, dualProof)
+}
+
+// TestIScan_DoesNotDropEntriesFromMultiKVTx checks that a page covering a
+// multi-KV tx (as ExecAllOps commits) surfaces every entry of that tx, not
+// just its first one.
+func TestIScan_DoesNotDropEntriesFromMultiKVTx(t *testing.T) {
+	db := makeTestDb(t)
+
+	_, err := db.ExecAllOps(&schema.Ops{
+		Operations: []*schema.Op{
+			{Operation: &schema.Op_KVs{KVs: &schema.KeyValue{Key: []byte("k1"), Value: []byte("v1")}}},
+			{Operation: &schema.Op_KVs{KVs: &schema.KeyValue{Key: []byte("k2"), Value: []byte("v2")}}},
+			{Operation: &schema.Op_KVs{KVs: &schema.KeyValue{Key: []byte("k3"), Value: []byte("v3")}}},
+		},
+	})
+	require.NoError(t, err)
+
+	page, err := db.IScan(&schema.IScanOptions{PageNumber: 0, PageSize: 3})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 3)
+	assert.False(t, page.More)
+
+	keys := []string{string(page.Items[0].Key), string(page.Items[1].Key), string(page.Items[2].Key)}
+	assert.ElementsMatch(t, []string{"k1", "k2", "k3"}, keys)
+}
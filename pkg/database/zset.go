@@ -0,0 +1,108 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// zsetKeyPrefix namespaces sorted-set entries away from plain KV entries, so
+// a ZAdd can never collide with a Set on the same underlying tbtree.
+const zsetKeyPrefix = "\x00zset|"
+
+// zsetKey encodes a sorted-set entry as a regular, score-ordered key:
+// \x00zset|<set>|<big-endian-float64-score>|<referenced-key>
+//
+// Encoding the score as a big-endian float64 bit pattern keeps entries in
+// score order under plain byte comparison for non-negative scores; negative
+// scores still compare correctly because we flip the sign bit (and invert
+// the rest when negative) before encoding, the usual trick for sortable
+// binary floats.
+func zsetKey(set []byte, score float64, key []byte) []byte {
+	k := make([]byte, 0, len(zsetKeyPrefix)+len(set)+1+8+1+len(key))
+	k = append(k, zsetKeyPrefix...)
+	k = append(k, set...)
+	k = append(k, '|')
+	k = append(k, encodeScore(score)...)
+	k = append(k, '|')
+	k = append(k, key...)
+	return k
+}
+
+// zsetPrefix is the common prefix of every entry belonging to set, used to
+// scope a ZScan to a single sorted set.
+func zsetPrefix(set []byte) []byte {
+	p := make([]byte, 0, len(zsetKeyPrefix)+len(set)+1)
+	p = append(p, zsetKeyPrefix...)
+	p = append(p, set...)
+	p = append(p, '|')
+	return p
+}
+
+func encodeScore(score float64) []byte {
+	bits := math.Float64bits(score)
+
+	if score >= 0 {
+		bits |= 1 << 63
+	} else {
+		bits = ^bits
+	}
+
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, bits)
+	return b
+}
+
+func decodeScore(b []byte) float64 {
+	bits := binary.BigEndian.Uint64(b)
+
+	if bits&(1<<63) != 0 {
+		bits &^= 1 << 63
+	} else {
+		bits = ^bits
+	}
+
+	return math.Float64frombits(bits)
+}
+
+// zsetReferencedKey extracts the key a zset entry points at, given the full
+// zset entry key and the set it belongs to.
+func zsetReferencedKey(set, zkey []byte) []byte {
+	prefix := zsetPrefix(set)
+	return zkey[len(prefix)+8+1:]
+}
+
+// zsetScoreLowerBound returns a key that sorts immediately below every real
+// entry at score, whatever its member: real entries are all
+// zsetScoreLowerBound(set, score)+member, and a string always sorts before
+// any other string that has it as a proper prefix.
+func zsetScoreLowerBound(set []byte, score float64) []byte {
+	return zsetKey(set, score, nil)
+}
+
+// zsetScoreUpperBound returns a key that sorts above every real entry at
+// score, yet still below every real entry at a higher score - the same
+// upper-bound trick prefixUpperBound uses for byte prefixes (see
+// pkg/tbtree/reader.go), applied per-score instead of per-prefix: '|' is the
+// delimiter byte separating score from member, and 0xff sorts above it, so
+// swapping it in bounds the whole bucket without colliding with any member.
+func zsetScoreUpperBound(set []byte, score float64) []byte {
+	b := zsetKey(set, score, nil)
+	b[len(b)-1] = 0xff
+	return b
+}
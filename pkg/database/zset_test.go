@@ -0,0 +1,117 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package database
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/codenotary/immudb/pkg/api/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeScore_PreservesOrderUnderByteCompare(t *testing.T) {
+	scores := []float64{-100.5, -1, 0, 0.1, 1, 100.25, 1e10}
+
+	for i := 1; i < len(scores); i++ {
+		prev := encodeScore(scores[i-1])
+		cur := encodeScore(scores[i])
+
+		assert.True(t, bytes.Compare(prev, cur) < 0, "encodeScore(%v) should sort before encodeScore(%v)", scores[i-1], scores[i])
+		assert.Equal(t, scores[i], decodeScore(cur))
+	}
+}
+
+func TestZsetKey_ReferencedKeyRoundTrip(t *testing.T) {
+	set := []byte("myset")
+	key := []byte("member-1")
+
+	zkey := zsetKey(set, 42.5, key)
+
+	assert.True(t, bytes.HasPrefix(zkey, zsetPrefix(set)))
+	assert.Equal(t, key, zsetReferencedKey(set, zkey))
+}
+
+func TestZAddAndZScan(t *testing.T) {
+	db := makeTestDb(t)
+
+	set := []byte("myset")
+
+	members := []struct {
+		key   string
+		score float64
+	}{
+		{"c", 3},
+		{"a", 1},
+		{"b", 2},
+	}
+
+	for _, m := range members {
+		_, err := db.Set(&schema.KeyValue{Key: []byte(m.key), Value: []byte(m.key)})
+		assert.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddOptions{Set: set, Key: []byte(m.key), Score: m.score})
+		assert.NoError(t, err)
+	}
+
+	list, err := db.ZScan(&schema.ZScanOptions{Set: set, Min: 0, Max: 10})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 3)
+	assert.Equal(t, "a", string(list.Items[0].Item.Key))
+	assert.Equal(t, "b", string(list.Items[1].Item.Key))
+	assert.Equal(t, "c", string(list.Items[2].Item.Key))
+}
+
+// TestZScan_BoundsAtExactScore checks that Min/Max exactly equal to a real
+// member's score are included or excluded according to MinExclusive and
+// MaxExclusive, not just treated as inclusive regardless of the flags.
+func TestZScan_BoundsAtExactScore(t *testing.T) {
+	db := makeTestDb(t)
+
+	set := []byte("myset")
+
+	members := []struct {
+		key   string
+		score float64
+	}{
+		{"a", 1},
+		{"b", 2},
+		{"c", 3},
+	}
+
+	for _, m := range members {
+		_, err := db.Set(&schema.KeyValue{Key: []byte(m.key), Value: []byte(m.key)})
+		assert.NoError(t, err)
+
+		_, err = db.ZAdd(&schema.ZAddOptions{Set: set, Key: []byte(m.key), Score: m.score})
+		assert.NoError(t, err)
+	}
+
+	list, err := db.ZScan(&schema.ZScanOptions{Set: set, Min: 1, Max: 3})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 3, "Min/Max exactly at the lowest/highest score should be inclusive by default")
+
+	list, err = db.ZScan(&schema.ZScanOptions{Set: set, Min: 1, Max: 3, MinExclusive: true})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 2, "MinExclusive should drop the member scored exactly at Min")
+	assert.Equal(t, "b", string(list.Items[0].Item.Key))
+
+	list, err = db.ZScan(&schema.ZScanOptions{Set: set, Min: 1, Max: 3, MaxExclusive: true})
+	assert.NoError(t, err)
+	assert.Len(t, list.Items, 2, "MaxExclusive should drop the member scored exactly at Max")
+	assert.Equal(t, "b", string(list.Items[1].Item.Key))
+}
@@ -16,11 +16,31 @@ limitations under the License.
 package tbtree
 
 import (
+	"encoding/binary"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+// monotonicInsertions inserts the big-endian uint32 encoding of every value
+// in [first, last] into tree, optionally committing after each insertion so
+// reader tests can exercise multiple leaf pages rather than a single
+// in-memory batch.
+func monotonicInsertions(t *testing.T, tree *TBtree, first, last int, commitEach bool) {
+	for i := first; i <= last; i++ {
+		key := make([]byte, 4)
+		binary.BigEndian.PutUint32(key, uint32(i))
+
+		_, _, err := tree.Insert(key, key)
+		assert.NoError(t, err)
+
+		if commitEach {
+			_, _, err = tree.Flush()
+			assert.NoError(t, err)
+		}
+	}
+}
+
 func TestReaderForEmptyTreeShouldReturnError(t *testing.T) {
 	tbtree, _ := New()
 
@@ -28,7 +48,7 @@ func TestReaderForEmptyTreeShouldReturnError(t *testing.T) {
 	assert.NotNil(t, root)
 	assert.NoError(t, err)
 
-	_, err = root.Reader(&ReaderSpec{prefix: []byte{0, 0, 0, 0}, ascOrder: true})
+	_, err = root.Reader(&ReaderSpec{prefix: []byte{0, 0, 0, 0}})
 	assert.Equal(t, ErrKeyNotFound, err)
 }
 
@@ -45,7 +65,6 @@ func TestReaderForNonEmptyTree(t *testing.T) {
 	rspec := &ReaderSpec{
 		prefix:      []byte{0, 0, 1, 250},
 		matchPrefix: true,
-		ascOrder:    true,
 	}
 	reader, err := root.Reader(rspec)
 	assert.NoError(t, err)
@@ -57,4 +76,63 @@ func TestReaderForNonEmptyTree(t *testing.T) {
 			break
 		}
 	}
+}
+
+func keyOf(n int) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint32(key, uint32(n))
+	return key
+}
+
+// TestReaderDescendingFromBoundedRange inserts 1..1000 monotonically, then
+// iterates descending from key 900 down to key 100 (both ends inclusive)
+// and asserts exactly 801 keys are visited, in reverse order.
+func TestReaderDescendingFromBoundedRange(t *testing.T) {
+	tbtree, err := NewWith(DefaultOptions().setMaxNodeSize(MinNodeSize))
+	assert.NoError(t, err)
+
+	monotonicInsertions(t, tbtree, 1, 1000, true)
+
+	root, err := tbtree.Root()
+	assert.NoError(t, err)
+
+	rspec := &ReaderSpec{
+		seekKey:       keyOf(900),
+		endKey:        keyOf(100),
+		inclusiveSeek: true,
+		inclusiveEnd:  true,
+		descOrder:     true,
+	}
+	reader, err := root.Reader(rspec)
+	assert.NoError(t, err)
+
+	visited := 0
+	expected := 900
+
+	for {
+		key, _, _, err := reader.Read()
+		if err != nil {
+			assert.Equal(t, ErrKeyNotFound, err)
+			break
+		}
+
+		assert.Equal(t, keyOf(expected), key)
+		expected--
+		visited++
+	}
+
+	assert.Equal(t, 801, visited)
+}
+
+// TestReaderDescendingPastEmptyTree mirrors
+// TestReaderForEmptyTreeShouldReturnError for the descending direction.
+func TestReaderDescendingPastEmptyTree(t *testing.T) {
+	tbtree, _ := New()
+
+	root, err := tbtree.Root()
+	assert.NotNil(t, root)
+	assert.NoError(t, err)
+
+	_, err = root.Reader(&ReaderSpec{prefix: []byte{0, 0, 0, 0}, matchPrefix: true, descOrder: true})
+	assert.Equal(t, ErrKeyNotFound, err)
 }
\ No newline at end of file
@@ -0,0 +1,192 @@
+/*
+Copyright 2019-2020 vChain, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tbtree
+
+import "bytes"
+
+// ReaderSpec specifies the range and order a Reader walks a snapshot in:
+//
+//   - prefix/matchPrefix scope the walk to keys sharing a common prefix
+//   - seekKey/endKey bound the walk on either side, each with its own
+//     inclusive flag so both open and closed ranges are expressible
+//   - descOrder selects descending or ascending (the original, still the
+//     default) traversal
+//
+// descOrder defaults to false so a zero-value ReaderSpec, or one built the
+// way every caller already did before these fields existed, still iterates
+// ascending.
+type ReaderSpec struct {
+	seekKey       []byte
+	prefix        []byte
+	matchPrefix   bool
+	endKey        []byte
+	inclusiveSeek bool
+	inclusiveEnd  bool
+	descOrder     bool
+}
+
+// Reader walks the entries of a tree snapshot according to a ReaderSpec, in
+// either direction, stopping when it runs past the end of its range on
+// either side.
+type Reader struct {
+	root   *Root
+	spec   *ReaderSpec
+	leaf   *leafNode
+	index  int
+	closed bool
+}
+
+// Reader returns a Reader over r positioned at spec.seekKey (or the natural
+// start/end of spec.prefix, if unset), ready to walk ascending or
+// descending according to spec.descOrder. It returns ErrKeyNotFound if the
+// starting position already falls outside spec's bounds, e.g. an empty
+// tree, or a prefix with no matching entries.
+func (r *Root) Reader(spec *ReaderSpec) (*Reader, error) {
+	if spec == nil {
+		return nil, ErrIllegalArguments
+	}
+
+	// A seek key derived from the prefix is inclusive when ascending (the
+	// prefix itself is a matching key) and exclusive when descending (its
+	// upper bound never matches).
+	seekKey := spec.seekKey
+	inclusiveSeek := spec.inclusiveSeek
+	if len(seekKey) == 0 && spec.matchPrefix {
+		if spec.descOrder {
+			seekKey = prefixUpperBound(spec.prefix)
+			inclusiveSeek = false
+		} else {
+			seekKey = spec.prefix
+			inclusiveSeek = true
+		}
+	}
+
+	leaf, index, err := r.findLeaf(seekKey, !spec.descOrder, inclusiveSeek)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &Reader{root: r, spec: spec, leaf: leaf, index: index}
+
+	if !reader.withinBounds(reader.currentKey()) {
+		return nil, ErrKeyNotFound
+	}
+
+	return reader, nil
+}
+
+// Read returns the next (key, value, ts) triple in the Reader's direction,
+// or ErrKeyNotFound once the range - or the tree - is exhausted.
+func (r *Reader) Read() ([]byte, []byte, uint64, error) {
+	if r.closed || r.leaf == nil {
+		return nil, nil, 0, ErrKeyNotFound
+	}
+
+	key := r.currentKey()
+	if !r.withinBounds(key) {
+		r.closed = true
+		return nil, nil, 0, ErrKeyNotFound
+	}
+
+	value, ts := r.leaf.valueAt(r.index)
+
+	r.advance()
+
+	return key, value, ts, nil
+}
+
+// Close releases the Reader. It is idempotent and safe to call more than
+// once.
+func (r *Reader) Close() error {
+	r.closed = true
+	r.leaf = nil
+	return nil
+}
+
+func (r *Reader) currentKey() []byte {
+	if r.leaf == nil {
+		return nil
+	}
+	return r.leaf.keyAt(r.index)
+}
+
+func (r *Reader) withinBounds(key []byte) bool {
+	if key == nil {
+		return false
+	}
+
+	if r.spec.matchPrefix && !bytes.HasPrefix(key, r.spec.prefix) {
+		return false
+	}
+
+	if len(r.spec.endKey) > 0 {
+		cmp := bytes.Compare(key, r.spec.endKey)
+
+		if r.spec.descOrder {
+			if cmp < 0 || (cmp == 0 && !r.spec.inclusiveEnd) {
+				return false
+			}
+		} else {
+			if cmp > 0 || (cmp == 0 && !r.spec.inclusiveEnd) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func (r *Reader) advance() {
+	if r.spec.descOrder {
+		r.index--
+		if r.index < 0 {
+			r.leaf = r.leaf.prev
+			if r.leaf != nil {
+				r.index = r.leaf.len() - 1
+			}
+		}
+	} else {
+		r.index++
+		if r.index >= r.leaf.len() {
+			r.leaf = r.leaf.next
+			r.index = 0
+		}
+	}
+
+	if r.leaf == nil {
+		r.closed = true
+	}
+}
+
+// prefixUpperBound returns the smallest key that compares greater than
+// every key sharing prefix, so a descending walk can seek to the last
+// matching entry the same way an ascending one seeks to the first by using
+// prefix itself. A prefix of all 0xff bytes (or empty) has no upper bound
+// within the key space, so nil is returned and the walk starts from the
+// tree's last key instead.
+func prefixUpperBound(prefix []byte) []byte {
+	upper := append([]byte{}, prefix...)
+
+	for i := len(upper) - 1; i >= 0; i-- {
+		if upper[i] < 0xff {
+			upper[i]++
+			return upper[:i+1]
+		}
+	}
+
+	return nil
+}